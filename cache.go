@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// cacheSchemaVersion is baked into the cache filename so a future change
+// to SubDict's shape can't be silently decoded into the wrong struct
+// layout; bump it whenever that shape changes.
+const cacheSchemaVersion = 2
+
+const cacheDirName = "wordle-vietnamese-assist"
+
+// cacheMeta is the sidecar file recording the HTTP validators for the
+// cached dictionary, so it can be conditionally revalidated instead of
+// re-downloaded every run.
+type cacheMeta struct {
+	SchemaVersion int    `json:"schema_version"`
+	ETag          string `json:"etag,omitempty"`
+	LastModified  string `json:"last_modified,omitempty"`
+}
+
+// cacheDir returns the directory the dictionary cache lives in, preferring
+// the OS user cache directory and falling back to the temp dir when it's
+// unavailable.
+func cacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil || dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, cacheDirName)
+}
+
+func cachePaths() (dataPath, metaPath string) {
+	base := fmt.Sprintf("dict_cache_v%d.json", cacheSchemaVersion)
+	dataPath = filepath.Join(cacheDir(), base)
+	metaPath = dataPath + ".meta.json"
+	return dataPath, metaPath
+}
+
+// readCache loads the dictionary and its validators from disk. Any read,
+// decode, or schema-version mismatch is reported as an error so the
+// caller falls back to a full download instead of trusting a corrupted or
+// stale-format cache.
+func readCache(dataPath, metaPath string) (map[string][]SubDict, *cacheMeta, error) {
+	metaBytes, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	var meta cacheMeta
+	if err := json.Unmarshal(metaBytes, &meta); err != nil {
+		return nil, nil, err
+	}
+	if meta.SchemaVersion != cacheSchemaVersion {
+		return nil, nil, fmt.Errorf("cache schema version %d does not match current version %d", meta.SchemaVersion, cacheSchemaVersion)
+	}
+
+	dataBytes, err := os.ReadFile(dataPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	var dict map[string][]SubDict
+	if err := json.Unmarshal(dataBytes, &dict); err != nil {
+		return nil, nil, err
+	}
+
+	return dict, &meta, nil
+}
+
+// writeCache persists dict and its validators to disk. Failures are not
+// fatal to the caller: a missing/unwritable cache just means the next run
+// downloads again.
+func writeCache(dataPath, metaPath string, dict map[string][]SubDict, meta *cacheMeta) error {
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0755); err != nil {
+		return err
+	}
+	dataBytes, err := json.Marshal(dict)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(dataPath, dataBytes, 0644); err != nil {
+		return err
+	}
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath, metaBytes, 0644)
+}
+
+// fetchDict issues a conditional GET against url, using prior's ETag/
+// Last-Modified as If-None-Match/If-Modified-Since unless force is set.
+// notModified is true on a 304 response, in which case dict is nil and
+// the caller should keep using its existing copy.
+func fetchDict(url string, prior *cacheMeta, force bool) (dict map[string][]SubDict, meta *cacheMeta, notModified bool, err error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if prior != nil && !force {
+		if prior.ETag != "" {
+			req.Header.Set("If-None-Match", prior.ETag)
+		}
+		if prior.LastModified != "" {
+			req.Header.Set("If-Modified-Since", prior.LastModified)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, prior, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, false, fmt.Errorf("failed to fetch: status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&dict); err != nil {
+		return nil, nil, false, err
+	}
+
+	meta = &cacheMeta{
+		SchemaVersion: cacheSchemaVersion,
+		ETag:          resp.Header.Get("ETag"),
+		LastModified:  resp.Header.Get("Last-Modified"),
+	}
+	return dict, meta, false, nil
+}
+
+// RefreshFailedError wraps the network error behind a forced refresh
+// (the "!refresh"/--refresh path) that fell back to the existing cache,
+// so callers can tell that apart from a non-forced revalidation, which on
+// a network error falls back to the cached copy with only a printed
+// warning and a nil error.
+type RefreshFailedError struct {
+	Err error
+}
+
+func (e *RefreshFailedError) Error() string {
+	return fmt.Sprintf("refresh failed, serving cached dictionary: %v", e.Err)
+}
+
+func (e *RefreshFailedError) Unwrap() error {
+	return e.Err
+}
+
+// loadDict loads the dictionary, conditionally revalidating the on-disk
+// cache against url rather than trusting it forever: with a cached copy
+// present it issues a conditional GET and only re-downloads on a 200, and
+// falls back to the cached copy (with a warning) if the network request
+// fails outright. force bypasses the cached validators and always
+// requests a fresh copy (the "!refresh"/--refresh path); if that forced
+// request fails, the cached copy is still returned but alongside a
+// *RefreshFailedError, since callers on that path need to report that the
+// refresh itself didn't happen rather than claiming success.
+func loadDict(url string, force bool) (map[string][]SubDict, error) {
+	dataPath, metaPath := cachePaths()
+	cachedDict, cachedMeta, cacheErr := readCache(dataPath, metaPath)
+	haveCache := cacheErr == nil
+
+	dict, meta, notModified, err := fetchDict(url, cachedMeta, force || !haveCache)
+	if err != nil {
+		if haveCache {
+			fmt.Printf("Warning: could not reach %s (%v), using cached dictionary\n", url, err)
+			if force {
+				return cachedDict, &RefreshFailedError{Err: err}
+			}
+			return cachedDict, nil
+		}
+		return nil, err
+	}
+
+	if notModified {
+		return cachedDict, nil
+	}
+
+	if err := writeCache(dataPath, metaPath, dict, meta); err != nil {
+		fmt.Printf("Warning: could not write dictionary cache: %v\n", err)
+	}
+	return dict, nil
+}
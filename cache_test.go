@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+var testDict = map[string][]SubDict{
+	"viet nam": {{Example: "vd", SubPos: "dt", Def: "def", Pos: "n"}},
+}
+
+func TestLoadDict_200PopulatesCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", "etag-1")
+		_ = json.NewEncoder(w).Encode(testDict)
+	}))
+	defer srv.Close()
+
+	dict, err := loadDict(srv.URL, false)
+	if err != nil {
+		t.Fatalf("loadDict: %v", err)
+	}
+	if !reflect.DeepEqual(dict, testDict) {
+		t.Errorf("got %v, want %v", dict, testDict)
+	}
+
+	dataPath, metaPath := cachePaths()
+	if _, err := os.Stat(dataPath); err != nil {
+		t.Errorf("expected cache data file to be written: %v", err)
+	}
+	if _, err := os.Stat(metaPath); err != nil {
+		t.Errorf("expected cache meta file to be written: %v", err)
+	}
+}
+
+func TestLoadDict_304ReusesCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("If-None-Match") == "etag-1" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", "etag-1")
+		_ = json.NewEncoder(w).Encode(testDict)
+	}))
+	defer srv.Close()
+
+	if _, err := loadDict(srv.URL, false); err != nil {
+		t.Fatalf("initial loadDict: %v", err)
+	}
+	dict, err := loadDict(srv.URL, false)
+	if err != nil {
+		t.Fatalf("revalidating loadDict: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("expected 2 requests (initial + conditional), got %d", got)
+	}
+	if !reflect.DeepEqual(dict, testDict) {
+		t.Errorf("got %v, want %v", dict, testDict)
+	}
+}
+
+func TestLoadDict_NetworkErrorFallsBackToCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", "etag-1")
+		_ = json.NewEncoder(w).Encode(testDict)
+	}))
+	url := srv.URL
+	if _, err := loadDict(url, false); err != nil {
+		t.Fatalf("initial loadDict: %v", err)
+	}
+	srv.Close()
+
+	dict, err := loadDict(url, false)
+	if err != nil {
+		t.Fatalf("expected fallback to cached dict, got error: %v", err)
+	}
+	if !reflect.DeepEqual(dict, testDict) {
+		t.Errorf("got %v, want %v", dict, testDict)
+	}
+}
+
+func TestLoadDict_ForcedRefreshNetworkErrorReturnsRefreshFailedError(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", "etag-1")
+		_ = json.NewEncoder(w).Encode(testDict)
+	}))
+	url := srv.URL
+	if _, err := loadDict(url, false); err != nil {
+		t.Fatalf("initial loadDict: %v", err)
+	}
+	srv.Close()
+
+	dict, err := loadDict(url, true)
+	var refreshErr *RefreshFailedError
+	if !errors.As(err, &refreshErr) {
+		t.Fatalf("expected a forced refresh to return a *RefreshFailedError, got %v", err)
+	}
+	if !reflect.DeepEqual(dict, testDict) {
+		t.Errorf("expected the stale cached dict to still be returned, got %v", dict)
+	}
+}
+
+func TestLoadDict_CorruptedCacheTriggersFreshDownload(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", "etag-1")
+		_ = json.NewEncoder(w).Encode(testDict)
+	}))
+	defer srv.Close()
+
+	dataPath, metaPath := cachePaths()
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(dataPath, []byte("not valid json"), 0644); err != nil {
+		t.Fatalf("WriteFile data: %v", err)
+	}
+	if err := os.WriteFile(metaPath, []byte(`{"schema_version":2}`), 0644); err != nil {
+		t.Fatalf("WriteFile meta: %v", err)
+	}
+
+	dict, err := loadDict(srv.URL, false)
+	if err != nil {
+		t.Fatalf("loadDict with corrupted cache: %v", err)
+	}
+	if !reflect.DeepEqual(dict, testDict) {
+		t.Errorf("got %v, want %v", dict, testDict)
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// suggestion is one ranked entry returned by suggestGuesses.
+type suggestion struct {
+	Word        string
+	Entropy     float64
+	IsCandidate bool
+}
+
+// candidateWords returns every dictionary word still consistent with the
+// accumulated constraints, i.e. the remaining answer set.
+func candidateWords(dict map[string][]SubDict, state *Constraints) []string {
+	var out []string
+	for word := range dict {
+		norm := strings.ToLower(removeDiacritics(normalizeSpaces(word)))
+		if state.Satisfies(flattenWord(norm)) {
+			out = append(out, word)
+		}
+	}
+	return out
+}
+
+// letterCounts tallies rune occurrences. guess/answer runes reaching this
+// package are diacritic-stripped and lowercased first (see
+// removeDiacritics), so nearly all of them are lowercase ASCII letters and
+// a fixed-size array holds their counts: entropy scoring calls
+// guessPattern once per guess/candidate pair, so avoiding a map
+// allocation there matters at dictionary scale. Any other rune (a hyphen,
+// a digit) goes to a map that is only allocated when one turns up.
+type letterCounts struct {
+	ascii [26]int
+	other map[rune]int
+}
+
+func (lc *letterCounts) add(r rune, n int) {
+	if r >= 'a' && r <= 'z' {
+		lc.ascii[r-'a'] += n
+		return
+	}
+	if lc.other == nil {
+		lc.other = make(map[rune]int)
+	}
+	lc.other[r] += n
+}
+
+func (lc *letterCounts) get(r rune) int {
+	if r >= 'a' && r <= 'z' {
+		return lc.ascii[r-'a']
+	}
+	return lc.other[r]
+}
+
+// guessPattern computes the base-3 Wordle feedback ('G'/'Y'/'B' per
+// position) for guessing g against answer a, both flattened to the same
+// length. Greens are resolved first, then yellows are awarded only
+// against the letters of a left over after greens are removed, which is
+// what makes duplicate letters (e.g. two 'a's in the guess but one in the
+// answer) score correctly.
+func guessPattern(g, a []rune) []byte {
+	n := len(g)
+	pattern := make([]byte, n)
+	var remaining letterCounts
+
+	for i := 0; i < n; i++ {
+		if g[i] == a[i] {
+			pattern[i] = 'G'
+		} else {
+			remaining.add(a[i], 1)
+		}
+	}
+	for i := 0; i < n; i++ {
+		if pattern[i] == 'G' {
+			continue
+		}
+		if remaining.get(g[i]) > 0 {
+			pattern[i] = 'Y'
+			remaining.add(g[i], -1)
+		} else {
+			pattern[i] = 'B'
+		}
+	}
+	return pattern
+}
+
+// patternKey packs a G/Y/B feedback pattern into a base-3 integer so
+// entropyForGuess's bucket map can key on a uint64 instead of a string,
+// which skips the allocation string(pattern) would otherwise do for
+// every guess/candidate pair.
+func patternKey(pattern []byte) uint64 {
+	var key uint64
+	for _, c := range pattern {
+		var d uint64
+		switch c {
+		case 'Y':
+			d = 1
+		case 'B':
+			d = 2
+		}
+		key = key*3 + d
+	}
+	return key
+}
+
+// entropyForGuess scores guess by the Shannon entropy of the feedback
+// pattern it would produce across answers, i.e. the expected information
+// gain of guessing it next.
+func entropyForGuess(guess []rune, answers [][]rune) float64 {
+	buckets := make(map[uint64]int, len(answers))
+	total := 0
+	for _, a := range answers {
+		if len(a) != len(guess) {
+			continue
+		}
+		buckets[patternKey(guessPattern(guess, a))]++
+		total++
+	}
+	if total == 0 {
+		return 0
+	}
+
+	var h float64
+	for _, n := range buckets {
+		p := float64(n) / float64(total)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// maxSuggestPoolSize caps how many words entropyForGuess scores against
+// each other. Scoring is exact but O(len(pool) x len(answers)), so an
+// uncapped "!suggest all" over a dictionary of tens of thousands of
+// entries (most of them still candidates early in a game) would no
+// longer be interactively responsive; beyond this size the ranking
+// trades completeness for staying fast. Candidates/pool are sorted
+// before trimming so the capped set is deterministic across runs.
+const maxSuggestPoolSize = 4000
+
+// suggestGuesses ranks candidate guesses by expected information gain
+// against the remaining candidate set. When candidatesOnly is true the
+// guess pool is restricted to those remaining candidates; otherwise the
+// full dictionary is scored, which can reveal a better-splitting guess
+// that isn't itself a possible answer. Pattern scoring runs on a small
+// worker pool, and both the candidate and guess pools are capped at
+// maxSuggestPoolSize to stay responsive on large dictionaries.
+func suggestGuesses(dict map[string][]SubDict, state *Constraints, candidatesOnly bool, topK int) []suggestion {
+	candidates := candidateWords(dict, state)
+	sort.Strings(candidates)
+	truncated := len(candidates) > maxSuggestPoolSize
+	if truncated {
+		candidates = candidates[:maxSuggestPoolSize]
+	}
+
+	candidateSet := make(map[string]bool, len(candidates))
+	answers := make([][]rune, 0, len(candidates))
+	for _, w := range candidates {
+		candidateSet[w] = true
+		norm := strings.ToLower(removeDiacritics(normalizeSpaces(w)))
+		answers = append(answers, flattenWord(norm))
+	}
+
+	pool := candidates
+	if !candidatesOnly {
+		pool = make([]string, 0, len(dict))
+		for w := range dict {
+			pool = append(pool, w)
+		}
+		sort.Strings(pool)
+		if len(pool) > maxSuggestPoolSize {
+			pool = pool[:maxSuggestPoolSize]
+			truncated = true
+		}
+	}
+
+	if truncated {
+		fmt.Printf("Note: capping entropy scoring at %d entries for responsiveness; ranking may be incomplete on this dictionary.\n", maxSuggestPoolSize)
+	}
+
+	workers := runtime.NumCPU()
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int, len(pool))
+	results := make([]suggestion, len(pool))
+	scored := make([]bool, len(pool))
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				word := pool[i]
+				norm := strings.ToLower(removeDiacritics(normalizeSpaces(word)))
+				guess := flattenWord(norm)
+				if state.WordLength != 0 && len(guess) != state.WordLength {
+					continue
+				}
+				results[i] = suggestion{
+					Word:        word,
+					Entropy:     entropyForGuess(guess, answers),
+					IsCandidate: candidateSet[word],
+				}
+				scored[i] = true
+			}
+		}()
+	}
+	for i := range pool {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	filtered := make([]suggestion, 0, len(results))
+	for i, r := range results {
+		if scored[i] {
+			filtered = append(filtered, r)
+		}
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		if filtered[i].Entropy != filtered[j].Entropy {
+			return filtered[i].Entropy > filtered[j].Entropy
+		}
+		if filtered[i].IsCandidate != filtered[j].IsCandidate {
+			return filtered[i].IsCandidate
+		}
+		return filtered[i].Word < filtered[j].Word
+	})
+
+	if topK > 0 && len(filtered) > topK {
+		filtered = filtered[:topK]
+	}
+	return filtered
+}
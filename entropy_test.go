@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGuessPattern_DuplicateLettersInGuess(t *testing.T) {
+	// Guessing "speed" against "abide": the guess has two 'e's but the
+	// answer has only one, so only the first (left to right) can be
+	// yellow and the second must be gray.
+	got := string(guessPattern([]rune("speed"), []rune("abide")))
+	want := "BBYBY"
+	if got != want {
+		t.Errorf("guessPattern(%q, %q) = %q, want %q", "speed", "abide", got, want)
+	}
+}
+
+func TestGuessPattern_GreenTakesPriorityOverYellow(t *testing.T) {
+	got := string(guessPattern([]rune("eerie"), []rune("rupee")))
+	want := "YBYBG"
+	if got != want {
+		t.Errorf("guessPattern(%q, %q) = %q, want %q", "eerie", "rupee", got, want)
+	}
+}
+
+func TestEntropyForGuess_ZeroForUniformOutcome(t *testing.T) {
+	// If every answer produces the same feedback pattern, the guess gives
+	// no information, so entropy should be 0.
+	answers := [][]rune{[]rune("aaaaa"), []rune("aaaaa")}
+	h := entropyForGuess([]rune("bbbbb"), answers)
+	if h != 0 {
+		t.Errorf("expected 0 bits for a uniform-outcome guess, got %v", h)
+	}
+}
+
+func TestEntropyForGuess_MaximalForEvenSplit(t *testing.T) {
+	// A guess that perfectly splits two equally likely answers into two
+	// distinct feedback buckets gives exactly 1 bit.
+	answers := [][]rune{[]rune("abc"), []rune("xyz")}
+	h := entropyForGuess([]rune("abc"), answers)
+	if math.Abs(h-1.0) > 1e-9 {
+		t.Errorf("expected 1 bit for an even two-way split, got %v", h)
+	}
+}
+
+func TestEntropyForGuess_SkipsMismatchedLengths(t *testing.T) {
+	answers := [][]rune{[]rune("abc"), []rune("abcd")}
+	h := entropyForGuess([]rune("abc"), answers)
+	if h != 0 {
+		t.Errorf("expected 0 bits when the only same-length answer matches exactly, got %v", h)
+	}
+}
+
+func TestSuggestGuesses_CandidatesOnlyRestrictsPool(t *testing.T) {
+	dict := map[string][]SubDict{
+		"ab": {{}},
+		"ac": {{}},
+		"zz": {{}},
+	}
+	state := NewConstraints()
+	if err := state.ApplyGuess("ab", "GB"); err != nil {
+		t.Fatalf("ApplyGuess: %v", err)
+	}
+
+	suggestions := suggestGuesses(dict, state, true, 10)
+	for _, s := range suggestions {
+		if s.Word == "zz" {
+			t.Errorf("did not expect %q (inconsistent with constraints and out of candidate pool) to be suggested", s.Word)
+		}
+	}
+}
+
+func TestSuggestGuesses_AllScoresFullDictionary(t *testing.T) {
+	dict := map[string][]SubDict{
+		"ab": {{}},
+		"ac": {{}},
+		"zz": {{}},
+	}
+	state := NewConstraints()
+	if err := state.ApplyGuess("ab", "GB"); err != nil {
+		t.Fatalf("ApplyGuess: %v", err)
+	}
+
+	suggestions := suggestGuesses(dict, state, false, 10)
+	found := false
+	for _, s := range suggestions {
+		if s.Word == "zz" {
+			found = true
+			if s.IsCandidate {
+				t.Errorf("expected %q to be scored but not flagged as a candidate", s.Word)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected !suggest all to include words outside the candidate set")
+	}
+}
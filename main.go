@@ -2,9 +2,8 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"flag"
 	"fmt"
-	"net/http"
 	"os"
 	"strings"
 	"unicode"
@@ -12,7 +11,7 @@ import (
 	"golang.org/x/text/unicode/norm"
 )
 
-const cacheFileName = "dict_cache.json"
+const dictionaryURL = "https://raw.githubusercontent.com/minhqnd/wordle-vietnamese/main/lib/dictionary_vi.json"
 
 type SubDict struct {
 	Example string `json:"example"`
@@ -26,53 +25,6 @@ type Dictionary struct {
 	SubDict []SubDict
 }
 
-type SyllableCond struct {
-	Length int
-}
-
-func loadDictFromURL(url string) (map[string][]SubDict, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch: status %d", resp.StatusCode)
-	}
-
-	var dict map[string][]SubDict
-	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(&dict)
-	if err != nil {
-		return nil, err
-	}
-	return dict, nil
-}
-
-func loadDict(url string) (map[string][]SubDict, error) {
-	cachePath := os.TempDir() + string(os.PathSeparator) + cacheFileName
-
-	if _, err := os.Stat(cachePath); err == nil {
-		data, err := os.ReadFile(cachePath)
-		if err == nil {
-			var dict map[string][]SubDict
-			if err := json.Unmarshal(data, &dict); err == nil {
-				return dict, nil
-			}
-		}
-	}
-
-	dict, err := loadDictFromURL(url)
-	if err != nil {
-		return nil, err
-	}
-
-	data, _ := json.Marshal(dict)
-	_ = os.WriteFile(cachePath, data, 0644)
-
-	return dict, nil
-}
-
 func removeDiacritics(str string) string {
 	t := norm.NFD.String(str)
 
@@ -93,51 +45,25 @@ func removeDiacritics(str string) string {
 	return b.String()
 }
 
-func matchWildcard(pattern, text, required, excluded string) bool {
-	pattern = normalizeSpaces(pattern)
-
-	pWords := strings.Split(pattern, " ")
-	tWords := strings.Split(text, " ")
-
-	if len(pWords) != len(tWords) {
+// matchWildcard checks text (a normalized, space-separated dictionary word)
+// against the extended pattern grammar compiled from pattern (see
+// pattern.go for '?', '*', character classes, "{m,n}" quantifiers and the
+// '_' whole-syllable wildcard), then checks the full word against the
+// accumulated guess/query constraints, so that green and yellow feedback
+// from earlier guesses still applies to letters the pattern pins down
+// explicitly. An unparseable pattern simply matches nothing.
+func matchWildcard(pattern, text string, c *Constraints) bool {
+	cp, err := getCompiledPattern(pattern)
+	if err != nil {
 		return false
 	}
 
-	var allWildcardRunes []rune
-
-	for i := range pWords {
-		pw := []rune(pWords[i])
-		tw := []rune(tWords[i])
-
-		if len(pw) != len(tw) {
-			return false
-		}
-
-		for j := range pw {
-			if pw[j] == '*' {
-				allWildcardRunes = append(allWildcardRunes, tw[j])
-			} else {
-				if pw[j] != tw[j] {
-					return false
-				}
-			}
-		}
-	}
-
-	wildcardText := string(allWildcardRunes)
-
-	for _, ch := range required {
-		if !strings.ContainsRune(wildcardText, ch) {
-			return false
-		}
-	}
-	for _, ch := range excluded {
-		if strings.ContainsRune(wildcardText, ch) {
-			return false
-		}
+	tWords := strings.Split(normalizeSpaces(text), " ")
+	if !cp.Match(tWords) {
+		return false
 	}
 
-	return true
+	return c.Satisfies(flattenWord(text))
 }
 
 func normalizeSpaces(s string) string {
@@ -148,7 +74,17 @@ func clearScreen() {
 	fmt.Print("\033[H\033[2J")
 }
 
-func ui(dict map[string][]SubDict) {
+// Session holds the REPL's state across turns: accumulated guess
+// feedback plus the active matching mode.
+type Session struct {
+	Constraints *Constraints
+	ToneMode    bool
+}
+
+// ui serves one REPL turn and returns the dictionary to use for the next
+// one, which is the same dict unless "!refresh" swapped in a freshly
+// revalidated copy.
+func ui(dict map[string][]SubDict, sess *Session) map[string][]SubDict {
 	usage := `
 Hướng dẫn sử dụng
 Tất cả các ký tự được nhập phải là chữ cái trong bảng chữ cái tiếng Anh.
@@ -161,6 +97,17 @@ Ví dụ:
 - "viet *** +a" sẽ khớp với các từ "viet nam", "viet tay" nhưng không khớp "viet ngu" vì từ đó không chứa "a".
 - "viet *** -oh +a" hoặc "viet *** +a -oh" sẽ kết hợp điều kiện từ hai ví dụ trên.
 
+Theo dõi phản hồi Wordle: Dùng "!guess <từ> <mẫu>" để ghi nhận một lượt đoán, với từ viết liền bằng dấu gạch dưới thay cho dấu cách và mẫu gồm các ký tự G (xanh lá - đúng vị trí), Y (vàng - có trong từ nhưng sai vị trí), B (xám - không có, trừ khi chữ đó đã xanh/vàng ở vị trí khác).
+Ví dụ: "!guess viet_nam GGYBGBY" ghi nhận lượt đoán "viet nam" và dùng kết quả đó để thu hẹp các lần so khớp tiếp theo. Lượt đoán được cộng dồn cho đến khi thoát chương trình.
+
+Gợi ý lượt đoán tiếp theo: Dùng "!suggest" để xếp hạng các từ theo lượng thông tin kỳ vọng (entropy) thu được dựa trên tập từ còn khả dĩ. Mặc định chỉ xét các từ còn khả dĩ làm lượt đoán; thêm "all" ("!suggest all") để xét toàn bộ từ điển, kể cả những từ chắc chắn không phải đáp án nhưng có thể giúp loại trừ nhiều khả năng hơn.
+
+Khớp theo dấu thanh: Mặc định mọi dấu đều bị bỏ qua khi so khớp. Dùng "!mode tone" để bật chế độ so khớp có dấu: các chữ cái không dấu trong mẫu vẫn khớp với mọi biến thể có dấu như trước, nhưng nếu bạn gõ đúng chữ có dấu (ví dụ "ế") thì từ khớp phải mang đúng dấu đó. Cú pháp "+"/"-" cũng áp dụng được cho chữ có dấu, ví dụ "+ế" chỉ khớp từ có chứa "ế". Dùng "!mode normal" để quay lại chế độ bỏ dấu mặc định.
+
+Cú pháp mẫu mở rộng (chế độ bỏ dấu): "*" vẫn khớp đúng một chữ cái bất kỳ như trước; "?" là ký hiệu mới, khớp một chuỗi chữ cái có độ dài bất kỳ, kể cả rỗng; "[aeiou]" khớp một trong các chữ cái liệt kê, "[^aeiou]" khớp chữ cái không nằm trong danh sách; thêm "{m,n}" ngay sau một token để lặp lại nó từ m đến n lần (ví dụ "a{2,3}"); "_" đứng một mình khớp với cả một âm tiết bất kỳ, độ dài tùy ý, ví dụ "viet _" khớp mọi từ hai âm tiết bắt đầu bằng "viet".
+
+Bộ nhớ đệm từ điển: Từ điển được lưu trên đĩa và được xác thực lại với máy chủ mỗi lần chạy, nên chỉ tải lại khi nguồn thực sự thay đổi. Dùng "!refresh" để buộc tải lại toàn bộ ngay lập tức thay vì đợi lần chạy sau.
+
 Mẹo: sử dụng khớp bao gồm với các chữ cái có trong từ nhưng sai vị trí và khớp loại trừ với các chữ cái không có trong từ từ các lần đoán trước.
 
 Usage:
@@ -174,6 +121,17 @@ For example:
 - "viet *** +a" will match the words "viet nam", "viet tay" but not "viet ngu" because it does not contain "a".
 - "viet *** -oh +a" or "viet *** +a -oh" will combine the conditions from the two examples above.
 
+Track real Wordle feedback: Use "!guess <word> <pattern>" to record a guess, with the word's syllables joined by underscores instead of spaces and the pattern made of G (green - right letter, right position), Y (yellow - in the word, wrong position) or B (gray - absent, unless the same letter is already green/yellow elsewhere).
+Example: "!guess viet_nam GGYBGBY" records the guess "viet nam" and uses the result to narrow down future matches. Guesses accumulate until you quit.
+
+Suggest the next guess: Use "!suggest" to rank words by expected information gain (entropy) against the remaining candidate set. By default only remaining candidates are considered as guesses; add "all" ("!suggest all") to score the full dictionary instead, including words that can't be the answer but may split the candidates better.
+
+Tone-aware matching: By default all diacritics are stripped before matching. Use "!mode tone" to match tones exactly: bare ASCII letters in the pattern still match any diacritic variant as before, but a tone-marked letter you type (e.g. "ế") must match that exact tone. The "+"/"-" syntax also accepts tone-marked letters, e.g. "+ế" only matches words containing "ế". Use "!mode normal" to go back to the default diacritic-stripped mode.
+
+Extended pattern grammar (diacritic-stripped mode): "*" still matches exactly one letter, as before; "?" is new and matches a run of letters of any length, including zero; "[aeiou]" matches one of the listed letters, "[^aeiou]" matches a letter not in the list; follow any token with "{m,n}" to repeat it m to n times (e.g. "a{2,3}"); "_" alone matches a whole syllable of any length, e.g. "viet _" matches any two-syllable word starting with "viet".
+
+Dictionary cache: The dictionary is cached on disk and revalidated against the server on every run, so it only re-downloads when the source actually changed. Use "!refresh" to force a full re-download right now instead of waiting for the next run.
+
 Tip: Use inclusive matches for letters that are in the word but in the wrong position, and exclusive matches for letters that are not in the word from previous guesses.
 `
 
@@ -182,56 +140,107 @@ Tip: Use inclusive matches for letters that are in the word but in the wrong pos
 	query, _ := reader.ReadString('\n')
 	query = strings.TrimSpace(query)
 
-	switch  query{
-		case "!quit":
-			os.Exit(0)
-		case "!help":
-			fmt.Println(usage)
-			os.Exit(0)
-		case "!clear":
-			clearScreen()
-		default:
-			queryNorm := strings.ToLower(removeDiacritics(query))
-
-			queryParts := strings.Split(queryNorm, " ")
-			queryPattern := ""
-			excludedChars := ""
-			requiredChars := ""
-
-			for _, part := range queryParts {
-				if len(part) > 0 && part[0] == '-' {
-					excludedChars += part[1:]
-				} else if len(part) > 0 && part[0] == '+' {
-					requiredChars += part[1:]
-				} else {
-					if queryPattern != "" {
-						queryPattern += " "
-					}
-					queryPattern += part
+	switch {
+	case query == "!quit":
+		os.Exit(0)
+	case query == "!help":
+		fmt.Println(usage)
+		os.Exit(0)
+	case query == "!clear":
+		clearScreen()
+	case strings.HasPrefix(query, "!guess"):
+		fields := strings.Fields(query)
+		if len(fields) != 3 {
+			fmt.Println("Usage: !guess <word_with_underscores> <GYB pattern>")
+			return dict
+		}
+		guess := strings.ToLower(removeDiacritics(strings.ReplaceAll(fields[1], "_", " ")))
+		if err := sess.Constraints.ApplyGuess(string(flattenWord(guess)), fields[2]); err != nil {
+			fmt.Printf("Could not apply guess: %v\n", err)
+			return dict
+		}
+		fmt.Println("Recorded. Future matches will respect this feedback.")
+	case query == "!suggest" || query == "!suggest all":
+		candidatesOnly := query == "!suggest"
+		suggestions := suggestGuesses(dict, sess.Constraints, candidatesOnly, 10)
+		if len(suggestions) == 0 {
+			fmt.Println("No candidates left to suggest from.")
+			return dict
+		}
+		for _, s := range suggestions {
+			fmt.Printf("> %s (%.3f bits)\n", s.Word, s.Entropy)
+		}
+	case query == "!mode tone":
+		sess.ToneMode = true
+		fmt.Println("Tone-aware matching enabled.")
+	case query == "!mode normal":
+		sess.ToneMode = false
+		fmt.Println("Tone-aware matching disabled.")
+	case query == "!refresh":
+		fmt.Println("Refreshing dictionary...")
+		refreshed, err := loadDict(dictionaryURL, true)
+		if err != nil {
+			fmt.Printf("Refresh failed: %v\n", err)
+			return dict
+		}
+		fmt.Println("Dictionary refreshed.")
+		return refreshed
+	case sess.ToneMode:
+		handleToneQuery(dict, sess, query)
+	default:
+		queryNorm := strings.ToLower(removeDiacritics(query))
+
+		queryParts := strings.Split(queryNorm, " ")
+		queryPattern := ""
+		queryState := sess.Constraints.Clone()
+
+		for _, part := range queryParts {
+			if len(part) > 0 && part[0] == '-' {
+				for _, ch := range part[1:] {
+					queryState.Exclude(ch)
+				}
+			} else if len(part) > 0 && part[0] == '+' {
+				for _, ch := range part[1:] {
+					queryState.Require(ch)
 				}
+			} else {
+				if queryPattern != "" {
+					queryPattern += " "
+				}
+				queryPattern += part
 			}
+		}
 
-			// fmt.Println(queryPattern)
-			// fmt.Println(excludedChars)
-			// fmt.Println(requiredChars)
-
-			for word := range dict {
-				wordNorm := normalizeSpaces(word)
-				wordNorm = strings.ToLower(removeDiacritics(wordNorm))
-				if matchWildcard(queryPattern, wordNorm, requiredChars, excludedChars) {
-					fmt.Printf("> %s\n", word)
-				}
+		for word := range dict {
+			wordNorm := normalizeSpaces(word)
+			wordNorm = strings.ToLower(removeDiacritics(wordNorm))
+			if matchWildcard(queryPattern, wordNorm, queryState) {
+				fmt.Printf("> %s\n", word)
 			}
+		}
 	}
+
+	return dict
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
+	}
+
+	refresh := flag.Bool("refresh", false, "force a full dictionary refresh, bypassing cached validators")
+	flag.Parse()
+
 	clearScreen()
 	fmt.Println("Loading dictionary...")
-	url := "https://raw.githubusercontent.com/minhqnd/wordle-vietnamese/main/lib/dictionary_vi.json"
-	dict, _ := loadDict(url)
-	fmt.Println("Type:\n- !help for usage\n- !quit for quit\n- !clear for clear screen")
+	dict, err := loadDict(dictionaryURL, *refresh)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	sess := &Session{Constraints: NewConstraints()}
+	fmt.Println("Type:\n- !help for usage\n- !guess <word> <pattern> to record Wordle feedback\n- !suggest [all] for a ranked next-guess suggestion\n- !mode tone|normal to toggle tone-aware matching\n- !refresh to force a dictionary re-download\n- !quit for quit\n- !clear for clear screen")
 	for {
-		ui(dict)
+		dict = ui(dict, sess)
 	}
 }
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type tokenKind int
+
+const (
+	tokLiteral tokenKind = iota
+	tokAnyOne            // '*': exactly one letter, the original pre-extension meaning
+	tokAnyRun            // '?': zero or more letters (new grammar only)
+	tokClass             // '[...]' / '[^...]'
+)
+
+// patternToken is one compiled grammar element within a syllable, along
+// with the repeat count it must satisfy (1,1 unless followed by a
+// "{m,n}" quantifier).
+type patternToken struct {
+	Kind     tokenKind
+	Literal  rune
+	Class    map[rune]bool
+	Negate   bool
+	MinCount int
+	MaxCount int // -1 means unbounded
+}
+
+// compiledSyllable is either a whole-syllable wildcard ("_", matches any
+// one syllable regardless of length) or a token list to match rune by
+// rune/run by run.
+type compiledSyllable struct {
+	Wildcard bool
+	Tokens   []patternToken
+}
+
+// compiledPattern is the parsed form of a raw query pattern: a top-level
+// list of syllables, each with its own token list.
+type compiledPattern struct {
+	Syllables []compiledSyllable
+}
+
+// patternCacheCapacity bounds how many distinct raw pattern strings stay
+// cached at once. The REPL only ever sees what one user types, but the
+// "serve" subcommand (server.go) feeds this cache straight from the
+// "?pattern=" query parameter of any client hitting /match, so without a
+// cap it would grow without bound on a long-running, internet-facing
+// process.
+const patternCacheCapacity = 1024
+
+// patternCacheEntry is one node of patternCacheList; patternCacheIndex
+// maps a raw pattern string to its node so lookups and LRU reordering
+// are both O(1).
+type patternCacheEntry struct {
+	raw string
+	cp  *compiledPattern
+}
+
+var (
+	patternCacheMu    sync.Mutex
+	patternCacheList  = list.New()
+	patternCacheIndex = make(map[string]*list.Element)
+)
+
+// getCompiledPattern compiles raw, or returns the cached compilation from
+// an earlier call with the same raw pattern string, since the REPL
+// re-matches the same pattern against every dictionary entry. The cache
+// is a fixed-capacity LRU (see patternCacheCapacity), evicting the least
+// recently used pattern once full.
+func getCompiledPattern(raw string) (*compiledPattern, error) {
+	patternCacheMu.Lock()
+	if el, ok := patternCacheIndex[raw]; ok {
+		patternCacheList.MoveToFront(el)
+		cp := el.Value.(*patternCacheEntry).cp
+		patternCacheMu.Unlock()
+		return cp, nil
+	}
+	patternCacheMu.Unlock()
+
+	cp, err := compilePattern(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	patternCacheMu.Lock()
+	defer patternCacheMu.Unlock()
+	if el, ok := patternCacheIndex[raw]; ok {
+		patternCacheList.MoveToFront(el)
+		return el.Value.(*patternCacheEntry).cp, nil
+	}
+	el := patternCacheList.PushFront(&patternCacheEntry{raw: raw, cp: cp})
+	patternCacheIndex[raw] = el
+	if patternCacheList.Len() > patternCacheCapacity {
+		oldest := patternCacheList.Back()
+		patternCacheList.Remove(oldest)
+		delete(patternCacheIndex, oldest.Value.(*patternCacheEntry).raw)
+	}
+	return cp, nil
+}
+
+// compilePattern parses the extended pattern grammar: '*' keeps its
+// original meaning of any single letter, '?' is new and matches any run
+// of zero or more letters, '[aeiou]'/'[^aeiou]' character classes, a
+// trailing "{m,n}" quantifier on the preceding token, and '_' as a
+// whole-syllable wildcard.
+func compilePattern(raw string) (*compiledPattern, error) {
+	syllables := strings.Split(normalizeSpaces(raw), " ")
+	cp := &compiledPattern{Syllables: make([]compiledSyllable, len(syllables))}
+
+	for i, s := range syllables {
+		if s == "_" {
+			cp.Syllables[i] = compiledSyllable{Wildcard: true}
+			continue
+		}
+		tokens, err := compileSyllableTokens(s)
+		if err != nil {
+			return nil, err
+		}
+		cp.Syllables[i] = compiledSyllable{Tokens: tokens}
+	}
+
+	return cp, nil
+}
+
+func compileSyllableTokens(s string) ([]patternToken, error) {
+	runes := []rune(s)
+	var tokens []patternToken
+
+	for i := 0; i < len(runes); {
+		var tok patternToken
+
+		switch runes[i] {
+		case '*':
+			tok = patternToken{Kind: tokAnyOne, MinCount: 1, MaxCount: 1}
+			i++
+		case '?':
+			tok = patternToken{Kind: tokAnyRun, MinCount: 0, MaxCount: -1}
+			i++
+		case '[':
+			end := i + 1
+			negate := false
+			if end < len(runes) && runes[end] == '^' {
+				negate = true
+				end++
+			}
+			classStart := end
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated character class in pattern %q", s)
+			}
+			class := make(map[rune]bool, end-classStart)
+			for _, cr := range runes[classStart:end] {
+				class[cr] = true
+			}
+			tok = patternToken{Kind: tokClass, Class: class, Negate: negate, MinCount: 1, MaxCount: 1}
+			i = end + 1
+		default:
+			tok = patternToken{Kind: tokLiteral, Literal: runes[i], MinCount: 1, MaxCount: 1}
+			i++
+		}
+
+		if i < len(runes) && runes[i] == '{' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '}' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated quantifier in pattern %q", s)
+			}
+			min, max, err := parseQuantifier(string(runes[i+1 : end]))
+			if err != nil {
+				return nil, fmt.Errorf("bad quantifier in pattern %q: %w", s, err)
+			}
+			tok.MinCount, tok.MaxCount = min, max
+			i = end + 1
+		}
+
+		tokens = append(tokens, tok)
+	}
+
+	return tokens, nil
+}
+
+// parseQuantifier parses the inside of a "{m,n}" (or "{m}" / "{m,}")
+// quantifier.
+func parseQuantifier(q string) (min, max int, err error) {
+	parts := strings.SplitN(q, ",", 2)
+	min, err = strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(parts) == 1 {
+		return min, min, nil
+	}
+	rest := strings.TrimSpace(parts[1])
+	if rest == "" {
+		return min, -1, nil
+	}
+	max, err = strconv.Atoi(rest)
+	if err != nil {
+		return 0, 0, err
+	}
+	return min, max, nil
+}
+
+// runeEq decides whether a pattern rune (from a literal token or a
+// character class member) matches a candidate rune. Plain matching uses
+// exact equality; tone-aware matching instead uses toneMatchRune so that
+// both modes can share the rest of the engine.
+type runeEq func(patternRune, textRune rune) bool
+
+func plainRuneEq(p, t rune) bool { return p == t }
+
+func tokenAcceptsRune(tok patternToken, r rune, eq runeEq) bool {
+	switch tok.Kind {
+	case tokAnyOne, tokAnyRun:
+		return true
+	case tokLiteral:
+		return eq(tok.Literal, r)
+	case tokClass:
+		matched := false
+		for cr := range tok.Class {
+			if eq(cr, r) {
+				matched = true
+				break
+			}
+		}
+		if tok.Negate {
+			return !matched
+		}
+		return matched
+	}
+	return false
+}
+
+func tokenMatchesRun(tok patternToken, text []rune, eq runeEq) bool {
+	for _, r := range text {
+		if !tokenAcceptsRune(tok, r, eq) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchTokens backtracks over how many runes each token consumes, so
+// that '?' and "{m,n}" quantifiers can match a variable number of
+// letters.
+func matchTokens(tokens []patternToken, text []rune, eq runeEq) bool {
+	if len(tokens) == 0 {
+		return len(text) == 0
+	}
+
+	tok := tokens[0]
+	rest := tokens[1:]
+
+	max := tok.MaxCount
+	if max < 0 || max > len(text) {
+		max = len(text)
+	}
+	for n := tok.MinCount; n <= max; n++ {
+		if !tokenMatchesRun(tok, text[:n], eq) {
+			continue
+		}
+		if matchTokens(rest, text[n:], eq) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match reports whether tWords (the space-split syllables of a
+// normalized dictionary word) satisfies the compiled pattern under
+// plain, diacritic-stripped rune equality.
+func (cp *compiledPattern) Match(tWords []string) bool {
+	return cp.MatchWith(tWords, plainRuneEq)
+}
+
+// MatchWith is like Match but compares pattern runes against candidate
+// runes with eq instead of plain equality, which is how tone-aware
+// matching (see tone.go) reuses the same compiled grammar and
+// backtracking engine instead of having its own separate notion of what
+// '*'/'?'/classes mean.
+func (cp *compiledPattern) MatchWith(tWords []string, eq runeEq) bool {
+	if len(cp.Syllables) != len(tWords) {
+		return false
+	}
+	for i, syl := range cp.Syllables {
+		if syl.Wildcard {
+			continue
+		}
+		if !matchTokens(syl.Tokens, []rune(tWords[i]), eq) {
+			return false
+		}
+	}
+	return true
+}
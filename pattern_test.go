@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestCompilePattern_StarStaysSingleLetter locks in the pre-existing '*'
+// examples from the usage text: each '*' must consume exactly one
+// letter, so "viet ***" matches only three-letter second syllables.
+func TestCompilePattern_StarStaysSingleLetter(t *testing.T) {
+	cp, err := compilePattern("viet ***")
+	if err != nil {
+		t.Fatalf("compilePattern: %v", err)
+	}
+
+	cases := []struct {
+		second string
+		want   bool
+	}{
+		{"nam", true},
+		{"hoa", true},
+		{"ngu", true},
+		{"n", false},
+		{"nguyen", false},
+	}
+	for _, c := range cases {
+		got := cp.Match([]string{"viet", c.second})
+		if got != c.want {
+			t.Errorf("viet %s: got %v, want %v", c.second, got, c.want)
+		}
+	}
+}
+
+func TestCompilePattern_QuestionMarkMatchesAnyRun(t *testing.T) {
+	cp, err := compilePattern("vi?t")
+	if err != nil {
+		t.Fatalf("compilePattern: %v", err)
+	}
+
+	cases := []struct {
+		word string
+		want bool
+	}{
+		{"viet", true},
+		{"vit", true},
+		{"viiiiet", true},
+		{"van", false},
+	}
+	for _, c := range cases {
+		got := cp.Match([]string{c.word})
+		if got != c.want {
+			t.Errorf("%s: got %v, want %v", c.word, got, c.want)
+		}
+	}
+}
+
+func TestCompilePattern_CharacterClass(t *testing.T) {
+	cp, err := compilePattern("[ct]an")
+	if err != nil {
+		t.Fatalf("compilePattern: %v", err)
+	}
+	if !cp.Match([]string{"can"}) {
+		t.Error("expected [ct]an to match can")
+	}
+	if !cp.Match([]string{"tan"}) {
+		t.Error("expected [ct]an to match tan")
+	}
+	if cp.Match([]string{"man"}) {
+		t.Error("did not expect [ct]an to match man")
+	}
+}
+
+func TestCompilePattern_NegatedCharacterClass(t *testing.T) {
+	cp, err := compilePattern("[^ct]an")
+	if err != nil {
+		t.Fatalf("compilePattern: %v", err)
+	}
+	if cp.Match([]string{"can"}) {
+		t.Error("did not expect [^ct]an to match can")
+	}
+	if !cp.Match([]string{"man"}) {
+		t.Error("expected [^ct]an to match man")
+	}
+}
+
+func TestCompilePattern_Quantifier(t *testing.T) {
+	cp, err := compilePattern("a{2,3}b")
+	if err != nil {
+		t.Fatalf("compilePattern: %v", err)
+	}
+	cases := []struct {
+		word string
+		want bool
+	}{
+		{"ab", false},
+		{"aab", true},
+		{"aaab", true},
+		{"aaaab", false},
+	}
+	for _, c := range cases {
+		got := cp.Match([]string{c.word})
+		if got != c.want {
+			t.Errorf("%s: got %v, want %v", c.word, got, c.want)
+		}
+	}
+}
+
+func TestCompilePattern_WholeSyllableWildcard(t *testing.T) {
+	cp, err := compilePattern("viet _")
+	if err != nil {
+		t.Fatalf("compilePattern: %v", err)
+	}
+	if !cp.Match([]string{"viet", "nam"}) {
+		t.Error("expected viet _ to match viet nam")
+	}
+	if !cp.Match([]string{"viet", "n"}) {
+		t.Error("expected the whole-syllable wildcard to match syllables of any length")
+	}
+	if cp.Match([]string{"viet", "nam", "extra"}) {
+		t.Error("did not expect viet _ to match a three-syllable word")
+	}
+}
+
+func TestCompilePattern_UnterminatedClassErrors(t *testing.T) {
+	if _, err := compilePattern("[abc"); err == nil {
+		t.Error("expected an error for an unterminated character class")
+	}
+}
+
+func TestCompilePattern_UnterminatedQuantifierErrors(t *testing.T) {
+	if _, err := compilePattern("a{2,3"); err == nil {
+		t.Error("expected an error for an unterminated quantifier")
+	}
+}
+
+func TestGetCompiledPattern_CachesByRawString(t *testing.T) {
+	raw := "cache-me-*-please"
+	first, err := getCompiledPattern(raw)
+	if err != nil {
+		t.Fatalf("getCompiledPattern: %v", err)
+	}
+	second, err := getCompiledPattern(raw)
+	if err != nil {
+		t.Fatalf("getCompiledPattern: %v", err)
+	}
+	if first != second {
+		t.Error("expected the second call with the same raw pattern to return the cached compilation")
+	}
+}
+
+// TestGetCompiledPattern_EvictsLeastRecentlyUsed guards against the
+// pattern cache growing without bound, since server.go's /match handler
+// feeds it directly from client-supplied query strings.
+func TestGetCompiledPattern_EvictsLeastRecentlyUsed(t *testing.T) {
+	patternCacheMu.Lock()
+	patternCacheList.Init()
+	for k := range patternCacheIndex {
+		delete(patternCacheIndex, k)
+	}
+	patternCacheMu.Unlock()
+
+	for i := 0; i < patternCacheCapacity+10; i++ {
+		if _, err := getCompiledPattern(fmt.Sprintf("evict-probe-%d", i)); err != nil {
+			t.Fatalf("getCompiledPattern: %v", err)
+		}
+	}
+
+	patternCacheMu.Lock()
+	size := patternCacheList.Len()
+	_, stillCached := patternCacheIndex["evict-probe-0"]
+	patternCacheMu.Unlock()
+
+	if size > patternCacheCapacity {
+		t.Errorf("expected the cache to stay at or under capacity %d, got %d entries", patternCacheCapacity, size)
+	}
+	if stillCached {
+		t.Error("expected the oldest pattern to have been evicted once the cache filled up")
+	}
+}
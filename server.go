@@ -0,0 +1,233 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// dictCache serves the dictionary in-process and transparently refreshes
+// it once cacheTTL has elapsed, instead of loading it once and keeping it
+// forever like the REPL path does.
+type dictCache struct {
+	mu      sync.RWMutex
+	url     string
+	ttl     time.Duration
+	dict    map[string][]SubDict
+	fetched time.Time
+}
+
+func newDictCache(url string, ttl time.Duration) (*dictCache, error) {
+	dict, err := loadDict(url, false)
+	if err != nil {
+		return nil, err
+	}
+	return &dictCache{url: url, ttl: ttl, dict: dict, fetched: time.Now()}, nil
+}
+
+// Get returns the current dictionary, conditionally revalidating it
+// against url first if the cache-ttl has elapsed. Since loadDict issues a
+// conditional GET, this is cheap when the upstream dictionary hasn't
+// actually changed, and a failed revalidation just keeps serving the last
+// known good dictionary.
+func (dc *dictCache) Get() map[string][]SubDict {
+	dc.mu.RLock()
+	stale := dc.ttl > 0 && time.Since(dc.fetched) >= dc.ttl
+	dict := dc.dict
+	dc.mu.RUnlock()
+
+	if !stale {
+		return dict
+	}
+
+	fresh, err := loadDict(dc.url, false)
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.fetched = time.Now()
+	if err == nil {
+		dc.dict = fresh
+	}
+	return dc.dict
+}
+
+type matchEntry struct {
+	Word    string    `json:"word"`
+	Entries []SubDict `json:"entries"`
+}
+
+type matchResponse struct {
+	Matches []matchEntry `json:"matches"`
+}
+
+func (dc *dictCache) handleMatch(w http.ResponseWriter, r *http.Request) {
+	dict := dc.Get()
+	q := r.URL.Query()
+	pattern := q.Get("pattern")
+	include := q.Get("include")
+	exclude := q.Get("exclude")
+
+	var matches []matchEntry
+	if q.Get("mode") == "tone" {
+		toneNorm := strings.ToLower(pattern)
+		for word, entries := range dict {
+			wordTone := normalizeSpaces(strings.ToLower(word))
+			if !toneMatchPattern(toneNorm, wordTone) {
+				continue
+			}
+			if !toneAwareAllow(flattenWord(wordTone), include, exclude) {
+				continue
+			}
+			matches = append(matches, matchEntry{Word: word, Entries: entries})
+		}
+	} else {
+		patternAscii := strings.ToLower(removeDiacritics(pattern))
+		state := NewConstraints()
+		for _, ch := range strings.ToLower(removeDiacritics(include)) {
+			state.Require(ch)
+		}
+		for _, ch := range strings.ToLower(removeDiacritics(exclude)) {
+			state.Exclude(ch)
+		}
+		for word, entries := range dict {
+			wordNorm := strings.ToLower(removeDiacritics(normalizeSpaces(word)))
+			if matchWildcard(patternAscii, wordNorm, state) {
+				matches = append(matches, matchEntry{Word: word, Entries: entries})
+			}
+		}
+	}
+
+	writeJSON(w, r, http.StatusOK, matchResponse{Matches: matches})
+}
+
+func (dc *dictCache) handleWord(w http.ResponseWriter, r *http.Request) {
+	word := strings.TrimPrefix(r.URL.Path, "/word/")
+	if word == "" {
+		http.Error(w, "missing word", http.StatusBadRequest)
+		return
+	}
+
+	entries, ok := dc.Get()[word]
+	if !ok {
+		http.Error(w, "word not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, Dictionary{Word: word, SubDict: entries})
+}
+
+type guessFeedback struct {
+	Word    string `json:"word"`
+	Pattern string `json:"pattern"`
+}
+
+type solveRequest struct {
+	Guesses []guessFeedback `json:"guesses"`
+	Suggest bool            `json:"suggest"`
+	All     bool            `json:"all"`
+	TopK    int             `json:"top_k"`
+}
+
+type solveResponse struct {
+	Candidates  []string     `json:"candidates"`
+	Suggestions []suggestion `json:"suggestions,omitempty"`
+}
+
+func (dc *dictCache) handleSolve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req solveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	dict := dc.Get()
+	state := NewConstraints()
+	for _, g := range req.Guesses {
+		guess := strings.ToLower(removeDiacritics(strings.ReplaceAll(g.Word, "_", " ")))
+		if err := state.ApplyGuess(string(flattenWord(guess)), g.Pattern); err != nil {
+			http.Error(w, fmt.Sprintf("invalid guess %q: %v", g.Word, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp := solveResponse{Candidates: candidateWords(dict, state)}
+	if req.Suggest {
+		topK := req.TopK
+		if topK <= 0 {
+			topK = 10
+		}
+		resp.Suggestions = suggestGuesses(dict, state, !req.All, topK)
+	}
+
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// writeJSON encodes payload as JSON, gzip-compressing the response when
+// the client advertises support for it, since /match result lists can be
+// large.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var out io.Writer = w
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(out).Encode(payload)
+}
+
+// runServe starts the HTTP JSON API subcommand ("serve"), exposing the
+// same matching and solving logic as the interactive REPL over HTTP.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	cacheTTL := fs.Duration("cache-ttl", 10*time.Minute, "how long to serve the dictionary before refreshing it")
+	url := fs.String("dict-url", dictionaryURL, "dictionary source URL")
+	fs.Parse(args)
+
+	dc, err := newDictCache(*url, *cacheTTL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load dictionary: %v\n", err)
+		os.Exit(1)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/match", dc.handleMatch)
+	mux.HandleFunc("/word/", dc.handleWord)
+	mux.HandleFunc("/solve", dc.handleSolve)
+
+	srv := &http.Server{Addr: *addr, Handler: mux}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		fmt.Printf("Serving on %s\n", *addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "server error: %v\n", err)
+		}
+	}()
+
+	<-ctx.Done()
+	fmt.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = srv.Shutdown(shutdownCtx)
+}
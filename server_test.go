@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestDictCache(dict map[string][]SubDict) *dictCache {
+	return &dictCache{dict: dict}
+}
+
+func TestHandleMatch_PatternInNormalMode(t *testing.T) {
+	dc := newTestDictCache(map[string][]SubDict{
+		"viet nam": {{Def: "vietnam"}},
+		"viet hoa": {{Def: "chinese"}},
+		"abc def":  {{Def: "unrelated"}},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/match?pattern=viet+***", nil)
+	w := httptest.NewRecorder()
+	dc.handleMatch(w, req)
+
+	var resp matchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d: %v", len(resp.Matches), resp.Matches)
+	}
+}
+
+func TestHandleMatch_ToneMode(t *testing.T) {
+	dc := newTestDictCache(map[string][]SubDict{
+		"viết nam": {{Def: "vietnam"}},
+		"viet nam": {{Def: "unmarked"}},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/match?mode=tone&pattern=vi%E1%BA%BFt+***", nil)
+	w := httptest.NewRecorder()
+	dc.handleMatch(w, req)
+
+	var resp matchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Matches) != 1 || resp.Matches[0].Word != "viết nam" {
+		t.Fatalf("expected only the exact-tone match, got %v", resp.Matches)
+	}
+}
+
+func TestHandleMatch_GzipsWhenAccepted(t *testing.T) {
+	dc := newTestDictCache(map[string][]SubDict{"abc": {{Def: "x"}}})
+
+	req := httptest.NewRequest(http.MethodGet, "/match?pattern=abc", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	dc.handleMatch(w, req)
+
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip Content-Encoding, got %q", w.Header().Get("Content-Encoding"))
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	defer gz.Close()
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("read gzip body: %v", err)
+	}
+	var resp matchResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		t.Fatalf("unmarshal gzip body: %v", err)
+	}
+	if len(resp.Matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(resp.Matches))
+	}
+}
+
+func TestHandleWord_FoundAndNotFound(t *testing.T) {
+	dc := newTestDictCache(map[string][]SubDict{
+		"viet nam": {{Def: "vietnam"}},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/word/viet%20nam", nil)
+	req.URL.Path = "/word/viet nam"
+	w := httptest.NewRecorder()
+	dc.handleWord(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for a known word, got %d", w.Code)
+	}
+
+	req2 := httptest.NewRequest(http.MethodGet, "/word/nope", nil)
+	req2.URL.Path = "/word/nope"
+	w2 := httptest.NewRecorder()
+	dc.handleWord(w2, req2)
+	if w2.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown word, got %d", w2.Code)
+	}
+}
+
+func TestHandleSolve_AppliesGuessesAndSuggests(t *testing.T) {
+	dc := newTestDictCache(map[string][]SubDict{
+		"ab": {{}},
+		"ac": {{}},
+		"zz": {{}},
+	})
+
+	body := bytes.NewBufferString(`{"guesses":[{"word":"ab","pattern":"GB"}],"suggest":true,"top_k":5}`)
+	req := httptest.NewRequest(http.MethodPost, "/solve", body)
+	w := httptest.NewRecorder()
+	dc.handleSolve(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp solveResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	// "ab" itself drops out: the gray 'b' caps the answer at zero 'b's.
+	if len(resp.Candidates) != 1 || resp.Candidates[0] != "ac" {
+		t.Fatalf("expected only %q to remain consistent with the guess, got %v", "ac", resp.Candidates)
+	}
+	if len(resp.Suggestions) == 0 {
+		t.Fatal("expected suggestions to be populated when suggest=true")
+	}
+}
+
+func TestHandleSolve_RejectsNonPost(t *testing.T) {
+	dc := newTestDictCache(map[string][]SubDict{})
+	req := httptest.NewRequest(http.MethodGet, "/solve", nil)
+	w := httptest.NewRecorder()
+	dc.handleSolve(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for a GET request, got %d", w.Code)
+	}
+}
+
+func TestHandleSolve_InvalidGuessReturns400(t *testing.T) {
+	dc := newTestDictCache(map[string][]SubDict{})
+	body := bytes.NewBufferString(`{"guesses":[{"word":"ab","pattern":"GBX"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/solve", body)
+	w := httptest.NewRecorder()
+	dc.handleSolve(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid guess pattern, got %d", w.Code)
+	}
+}
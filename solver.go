@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraints accumulates everything we know about the answer from the
+// Wordle-style feedback the user has reported across one or more guesses.
+// Positions are indexed into the flattened (space-stripped) word, so they
+// line up across multi-syllable entries the same way a guess does.
+type Constraints struct {
+	Green           map[int]rune
+	YellowForbidden map[int]map[rune]bool
+	MinCounts       map[rune]int
+	MaxCounts       map[rune]int
+	// WordLength is the flattened letter count of the answer, learned from
+	// the first recorded guess. Zero means no guess has been recorded yet,
+	// so length is not yet constrained.
+	WordLength int
+}
+
+// NewConstraints returns an empty constraint set.
+func NewConstraints() *Constraints {
+	return &Constraints{
+		Green:           make(map[int]rune),
+		YellowForbidden: make(map[int]map[rune]bool),
+		MinCounts:       make(map[rune]int),
+		MaxCounts:       make(map[rune]int),
+	}
+}
+
+// Clone returns a deep copy so callers can layer ephemeral, query-scoped
+// conditions (e.g. the +/- syntax) on top of the accumulated guess state
+// without mutating it.
+func (c *Constraints) Clone() *Constraints {
+	clone := NewConstraints()
+	for k, v := range c.Green {
+		clone.Green[k] = v
+	}
+	for pos, set := range c.YellowForbidden {
+		cp := make(map[rune]bool, len(set))
+		for ch := range set {
+			cp[ch] = true
+		}
+		clone.YellowForbidden[pos] = cp
+	}
+	for k, v := range c.MinCounts {
+		clone.MinCounts[k] = v
+	}
+	for k, v := range c.MaxCounts {
+		clone.MaxCounts[k] = v
+	}
+	clone.WordLength = c.WordLength
+	return clone
+}
+
+// Require marks ch as required to appear at least once (the legacy "+ch"
+// syntax).
+func (c *Constraints) Require(ch rune) {
+	if c.MinCounts[ch] < 1 {
+		c.MinCounts[ch] = 1
+	}
+}
+
+// Exclude marks ch as absent from the word entirely (the legacy "-ch"
+// syntax).
+func (c *Constraints) Exclude(ch rune) {
+	c.MaxCounts[ch] = 0
+}
+
+// flattenWord strips syllable-separating spaces, returning the rune
+// sequence that guess feedback positions are indexed against.
+func flattenWord(word string) []rune {
+	var out []rune
+	for _, r := range word {
+		if r == ' ' {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// ApplyGuess folds one reported guess into the accumulated constraints.
+// guess is the flattened (space-stripped) guessed word, normalized the
+// same way dictionary entries are (lowercased, diacritics stripped).
+// pattern is a same-length string of 'G' (green: correct letter and
+// position), 'Y' (yellow: present, wrong position) or 'B' (gray: absent).
+//
+// Duplicate letters are handled the way real Wordle does it: a gray on a
+// letter that also shows green/yellow elsewhere in the same guess does not
+// mean the letter is absent, it means the word contains no more copies of
+// it than the green/yellow occurrences already account for.
+func (c *Constraints) ApplyGuess(guess, pattern string) error {
+	letters := []rune(guess)
+	colors := []rune(strings.ToUpper(pattern))
+	if len(letters) != len(colors) {
+		return fmt.Errorf("guess %q has %d letters but pattern %q has %d", guess, len(letters), pattern, len(colors))
+	}
+	if c.WordLength == 0 {
+		c.WordLength = len(letters)
+	} else if c.WordLength != len(letters) {
+		return fmt.Errorf("guess %q has %d letters but earlier guesses were %d letters", guess, len(letters), c.WordLength)
+	}
+
+	nonGrayCounts := make(map[rune]int)
+	isGray := make(map[rune]bool)
+
+	for i, ch := range letters {
+		switch colors[i] {
+		case 'G':
+			c.Green[i] = ch
+			nonGrayCounts[ch]++
+		case 'Y':
+			if c.YellowForbidden[i] == nil {
+				c.YellowForbidden[i] = make(map[rune]bool)
+			}
+			c.YellowForbidden[i][ch] = true
+			nonGrayCounts[ch]++
+		case 'B':
+			isGray[ch] = true
+		default:
+			return fmt.Errorf("unknown feedback color %q (expected G, Y or B)", string(colors[i]))
+		}
+	}
+
+	for ch, n := range nonGrayCounts {
+		if c.MinCounts[ch] < n {
+			c.MinCounts[ch] = n
+		}
+	}
+	for ch := range isGray {
+		n := nonGrayCounts[ch]
+		if existing, ok := c.MaxCounts[ch]; !ok || n < existing {
+			c.MaxCounts[ch] = n
+		}
+	}
+
+	return nil
+}
+
+// Satisfies reports whether the flattened word text is still consistent
+// with every green, yellow and count constraint known so far.
+func (c *Constraints) Satisfies(text []rune) bool {
+	if c.WordLength != 0 && len(text) != c.WordLength {
+		return false
+	}
+	for pos, ch := range c.Green {
+		if pos >= len(text) || text[pos] != ch {
+			return false
+		}
+	}
+	for pos, forbidden := range c.YellowForbidden {
+		if pos < len(text) && forbidden[text[pos]] {
+			return false
+		}
+	}
+
+	counts := make(map[rune]int)
+	for _, r := range text {
+		counts[r]++
+	}
+	for ch, min := range c.MinCounts {
+		if counts[ch] < min {
+			return false
+		}
+	}
+	for ch, max := range c.MaxCounts {
+		if counts[ch] > max {
+			return false
+		}
+	}
+
+	return true
+}
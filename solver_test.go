@@ -0,0 +1,100 @@
+package main
+
+import "testing"
+
+func TestApplyGuess_GreenYellowGray(t *testing.T) {
+	c := NewConstraints()
+	if err := c.ApplyGuess("canto", "GYBBY"); err != nil {
+		t.Fatalf("ApplyGuess: %v", err)
+	}
+	if c.Green[0] != 'c' {
+		t.Errorf("expected position 0 green 'c', got %q", c.Green[0])
+	}
+	if !c.YellowForbidden[1]['a'] {
+		t.Errorf("expected position 1 to forbid yellow letter 'a'")
+	}
+	if !c.YellowForbidden[4]['o'] {
+		t.Errorf("expected position 4 to forbid yellow letter 'o'")
+	}
+	if c.MaxCounts['n'] != 0 {
+		t.Errorf("expected gray-only letter 'n' capped at 0, got %d", c.MaxCounts['n'])
+	}
+	if c.WordLength != 5 {
+		t.Errorf("expected word length 5, got %d", c.WordLength)
+	}
+}
+
+func TestApplyGuess_GrayDoesNotExcludeDuplicateAlreadyMarked(t *testing.T) {
+	// Guessing "sense" against an answer containing exactly one 's' marks
+	// the first 's' green/yellow and the second gray; the gray should cap
+	// the count at what's already accounted for, not exclude 's' outright.
+	c := NewConstraints()
+	if err := c.ApplyGuess("sense", "GYBBB"); err != nil {
+		t.Fatalf("ApplyGuess: %v", err)
+	}
+	if max, ok := c.MaxCounts['s']; !ok || max != 1 {
+		t.Errorf("expected MaxCounts['s'] == 1, got %d (ok=%v)", max, ok)
+	}
+	if !c.Satisfies([]rune("sited")) {
+		t.Errorf("expected a word with exactly one 's' to still satisfy constraints")
+	}
+}
+
+func TestApplyGuess_LengthMismatchErrors(t *testing.T) {
+	c := NewConstraints()
+	if err := c.ApplyGuess("abc", "GYB B"); err == nil {
+		t.Fatal("expected error for mismatched guess/pattern lengths")
+	}
+}
+
+func TestApplyGuess_InconsistentWordLengthErrors(t *testing.T) {
+	c := NewConstraints()
+	if err := c.ApplyGuess("abcde", "GGGGG"); err != nil {
+		t.Fatalf("first ApplyGuess: %v", err)
+	}
+	if err := c.ApplyGuess("abcdef", "GGGGGG"); err == nil {
+		t.Fatal("expected error for a guess whose length contradicts an earlier guess")
+	}
+}
+
+func TestApplyGuess_UnknownColorErrors(t *testing.T) {
+	c := NewConstraints()
+	if err := c.ApplyGuess("abc", "GYX"); err == nil {
+		t.Fatal("expected error for an unrecognized feedback color")
+	}
+}
+
+func TestSatisfies_GreenYellowAndCounts(t *testing.T) {
+	c := NewConstraints()
+	if err := c.ApplyGuess("abcde", "GYBBB"); err != nil {
+		t.Fatalf("ApplyGuess: %v", err)
+	}
+
+	if !c.Satisfies([]rune("awbxy")) {
+		t.Errorf("expected a candidate matching green/yellow/count constraints to satisfy")
+	}
+	if c.Satisfies([]rune("abxxx")) {
+		t.Errorf("did not expect a word with 'b' back at the yellow position to satisfy")
+	}
+	if c.Satisfies([]rune("axzwy")) {
+		t.Errorf("did not expect a word missing the required yellow letter 'b' to satisfy")
+	}
+}
+
+func TestClone_IsIndependentOfOriginal(t *testing.T) {
+	c := NewConstraints()
+	if err := c.ApplyGuess("abc", "GYB"); err != nil {
+		t.Fatalf("ApplyGuess: %v", err)
+	}
+
+	clone := c.Clone()
+	clone.Exclude('z')
+	clone.Require('q')
+
+	if _, ok := c.MaxCounts['z']; ok {
+		t.Errorf("mutating the clone should not affect the original's MaxCounts")
+	}
+	if _, ok := c.MinCounts['q']; ok {
+		t.Errorf("mutating the clone should not affect the original's MinCounts")
+	}
+}
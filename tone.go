@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// decomposeRuneNFD splits a single rune into its NFD base letter plus any
+// trailing combining marks (tone/diacritic marks for Vietnamese vowels).
+func decomposeRuneNFD(r rune) (base rune, marks []rune) {
+	for _, rr := range norm.NFD.String(string(r)) {
+		if unicode.Is(unicode.Mn, rr) {
+			marks = append(marks, rr)
+			continue
+		}
+		if base == 0 {
+			base = rr
+		}
+	}
+	return base, marks
+}
+
+// foldBase normalizes a base letter the same way removeDiacritics does,
+// so bare ASCII patterns keep matching đ/Đ as a variant of d/D.
+func foldBase(r rune) rune {
+	switch r {
+	case 'đ':
+		return 'd'
+	case 'Đ':
+		return 'D'
+	default:
+		return unicode.ToLower(r)
+	}
+}
+
+func marksEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// toneMatchRune compares a pattern rune against a candidate rune. A bare
+// ASCII pattern rune (no combining marks of its own) matches any diacritic
+// variant of that base letter, same as the fully diacritic-stripped
+// matching used elsewhere. A pattern rune that itself carries tone marks
+// (e.g. 'ế') only matches candidates whose base letter carries exactly
+// those same marks.
+func toneMatchRune(p, t rune) bool {
+	pBase, pMarks := decomposeRuneNFD(p)
+	tBase, tMarks := decomposeRuneNFD(t)
+	if foldBase(pBase) != foldBase(tBase) {
+		return false
+	}
+	if len(pMarks) == 0 {
+		return true
+	}
+	return marksEqual(pMarks, tMarks)
+}
+
+// toneMatchPattern is the tone-aware counterpart to matchWildcard: it
+// compiles pattern through the same extended grammar (pattern.go), so
+// '*'/'?'/classes/quantifiers/'_' mean the same thing in both modes, but
+// matches non-wildcard pattern runes with toneMatchRune instead of plain
+// equality, so a tone-marked pattern rune (e.g. 'ế') only matches
+// candidates carrying that exact tone.
+func toneMatchPattern(pattern, text string) bool {
+	cp, err := getCompiledPattern(pattern)
+	if err != nil {
+		return false
+	}
+
+	tWords := strings.Split(normalizeSpaces(text), " ")
+	return cp.MatchWith(tWords, toneMatchRune)
+}
+
+// containsToneAware reports whether flatText contains a letter matching ch
+// under tone-aware rules: a bare ch matches any diacritic variant of its
+// base letter, a tone-marked ch requires an exact mark match.
+func containsToneAware(flatText []rune, ch rune) bool {
+	for _, t := range flatText {
+		if toneMatchRune(ch, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// toneAwareAllow checks a flattened word against the tone-aware +/-
+// syntax: every rune of include must appear (tone-aware) and no rune of
+// exclude may.
+func toneAwareAllow(flatText []rune, include, exclude string) bool {
+	for _, ch := range strings.ToLower(include) {
+		if !containsToneAware(flatText, ch) {
+			return false
+		}
+	}
+	for _, ch := range strings.ToLower(exclude) {
+		if containsToneAware(flatText, ch) {
+			return false
+		}
+	}
+	return true
+}
+
+// handleToneQuery parses and answers a query in tone-aware mode: pattern
+// runes and +/- letters keep their diacritics instead of being folded
+// away, so the caller can pin down an exact tone (e.g. "+ế") while bare
+// ASCII letters still match any diacritic variant as usual. Guess
+// feedback recorded via !guess still applies, via the ordinary
+// diacritic-stripped word form.
+func handleToneQuery(dict map[string][]SubDict, sess *Session, query string) {
+	queryNorm := strings.ToLower(query)
+
+	queryParts := strings.Split(queryNorm, " ")
+	queryPattern := ""
+	include, exclude := "", ""
+
+	for _, part := range queryParts {
+		if len(part) > 0 && part[0] == '-' {
+			exclude += part[1:]
+		} else if len(part) > 0 && part[0] == '+' {
+			include += part[1:]
+		} else {
+			if queryPattern != "" {
+				queryPattern += " "
+			}
+			queryPattern += part
+		}
+	}
+
+	for word := range dict {
+		asciiNorm := strings.ToLower(removeDiacritics(normalizeSpaces(word)))
+		if !sess.Constraints.Satisfies(flattenWord(asciiNorm)) {
+			continue
+		}
+
+		toneNorm := normalizeSpaces(strings.ToLower(word))
+		if !toneMatchPattern(queryPattern, toneNorm) {
+			continue
+		}
+
+		if toneAwareAllow(flattenWord(toneNorm), include, exclude) {
+			fmt.Printf("> %s\n", word)
+		}
+	}
+}
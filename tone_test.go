@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestToneMatchRune_BareAsciiMatchesAnyDiacriticVariant(t *testing.T) {
+	if !toneMatchRune('e', 'ế') {
+		t.Error("expected bare 'e' to match the diacritic variant 'ế'")
+	}
+	if !toneMatchRune('e', 'e') {
+		t.Error("expected bare 'e' to match plain 'e'")
+	}
+	if toneMatchRune('e', 'a') {
+		t.Error("did not expect 'e' to match a different base letter")
+	}
+}
+
+func TestToneMatchRune_ToneMarkedRuneRequiresExactTone(t *testing.T) {
+	if !toneMatchRune('ế', 'ế') {
+		t.Error("expected 'ế' to match itself")
+	}
+	if toneMatchRune('ế', 'é') {
+		t.Error("did not expect 'ế' to match a different tone mark 'é'")
+	}
+	if toneMatchRune('ế', 'e') {
+		t.Error("did not expect 'ế' to match the bare base letter")
+	}
+}
+
+func TestToneMatchRune_DStrokeFoldsLikeRemoveDiacritics(t *testing.T) {
+	if !toneMatchRune('d', 'đ') {
+		t.Error("expected bare 'd' to match 'đ', same as removeDiacritics folding")
+	}
+}
+
+func TestToneMatchPattern_AgreesWithAsciiModeOnBareLetters(t *testing.T) {
+	if !toneMatchPattern("viet ***", "viet nam") {
+		t.Error("expected viet *** to match viet nam in tone mode, same as ascii mode")
+	}
+	if toneMatchPattern("viet ***", "viet nguyen") {
+		t.Error("did not expect viet *** to match a 6-letter second syllable in tone mode")
+	}
+}
+
+func TestToneMatchPattern_ExactToneRequired(t *testing.T) {
+	if !toneMatchPattern("vi?t", "viết") {
+		t.Error("expected vi?t to match viết: the bare pattern letters fold, ? matches the run")
+	}
+	if !toneMatchPattern("ế", "ế") {
+		t.Error("expected an exact tone-marked pattern letter to match the same tone")
+	}
+	if toneMatchPattern("ế", "é") {
+		t.Error("did not expect a tone-marked pattern letter to match a different tone")
+	}
+}
+
+func TestToneAwareAllow_IncludeAndExclude(t *testing.T) {
+	flat := flattenWord("viết nam")
+	if !toneAwareAllow(flat, "ế", "") {
+		t.Error("expected include to require the tone-aware letter 'ế'")
+	}
+	if toneAwareAllow(flat, "é", "") {
+		t.Error("did not expect include 'é' to match a word containing the different tone 'ế'")
+	}
+	if toneAwareAllow(flat, "", "ế") {
+		t.Error("expected exclude 'ế' to reject a word containing it")
+	}
+}